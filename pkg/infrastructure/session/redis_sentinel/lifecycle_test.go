@@ -0,0 +1,123 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis_sentinel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v7"
+)
+
+// TestSessionReleaseEmptyValuesDeletesKey checks that releasing a session
+// with no values issues a DEL instead of writing an empty blob back.
+func TestSessionReleaseEmptyValuesDeletesKey(t *testing.T) {
+	rp := newScanTestProvider(t)
+	ctx := context.Background()
+
+	store, err := rp.SessionRead(ctx, "sid-1")
+	if err != nil {
+		t.Fatalf("SessionRead: %v", err)
+	}
+	if err := store.Set(ctx, "k", "v"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	store.SessionRelease(ctx, nil)
+	if n, err := rp.poollist.Exists(rp.key("sid-1")).Result(); err != nil || n == 0 {
+		t.Fatalf("key should exist after releasing a non-empty session, exists=%d err=%v", n, err)
+	}
+
+	store, err = rp.SessionRead(ctx, "sid-1")
+	if err != nil {
+		t.Fatalf("SessionRead: %v", err)
+	}
+	if err := store.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	store.SessionRelease(ctx, nil)
+
+	if n, err := rp.poollist.Exists(rp.key("sid-1")).Result(); err != nil || n != 0 {
+		t.Fatalf("key should be DELeted after releasing an empty session, exists=%d err=%v", n, err)
+	}
+}
+
+// TestSessionReadSlidesTTL checks that reading an existing session refreshes
+// its TTL, so an active session doesn't expire mid-use.
+func TestSessionReadSlidesTTL(t *testing.T) {
+	rp := newScanTestProvider(t)
+	ctx := context.Background()
+
+	store, err := rp.SessionRead(ctx, "sid-1")
+	if err != nil {
+		t.Fatalf("SessionRead: %v", err)
+	}
+	if err := store.Set(ctx, "k", "v"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	store.SessionRelease(ctx, nil)
+
+	key := rp.key("sid-1")
+	if err := rp.poollist.Expire(key, time.Second).Err(); err != nil {
+		t.Fatalf("seed short TTL: %v", err)
+	}
+
+	if _, err := rp.SessionRead(ctx, "sid-1"); err != nil {
+		t.Fatalf("SessionRead: %v", err)
+	}
+
+	ttl, err := rp.poollist.TTL(key).Result()
+	if err != nil {
+		t.Fatalf("TTL: %v", err)
+	}
+	if ttl <= time.Second {
+		t.Fatalf("TTL = %v, want > 1s (SessionRead should have slid it back to maxlifetime)", ttl)
+	}
+}
+
+// TestSessionStoreErrReflectsReleaseFailure checks that Err() surfaces a
+// failed SessionRelease, since SessionRelease itself can't return an error
+// without breaking the session.Store interface.
+func TestSessionStoreErrReflectsReleaseFailure(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rp := &Provider{
+		maxlifetime: 60,
+		storageMode: storageModeGeneric,
+		serializer:  serializers[defaultSerializerName],
+		keyPrefix:   DefaultKeyPrefix,
+		poollist:    redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+	}
+	ctx := context.Background()
+
+	store, err := rp.SessionRead(ctx, "sid-1")
+	if err != nil {
+		t.Fatalf("SessionRead: %v", err)
+	}
+	if err := store.Set(ctx, "k", "v"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	mr.Close()
+	store.SessionRelease(ctx, nil)
+
+	rs, ok := store.(*SessionStore)
+	if !ok {
+		t.Fatalf("store is %T, want *SessionStore", store)
+	}
+	if rs.Err() == nil {
+		t.Fatal("Err() = nil, want the connection failure from SessionRelease")
+	}
+}
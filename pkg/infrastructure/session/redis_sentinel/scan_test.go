@@ -0,0 +1,85 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis_sentinel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v7"
+)
+
+// newScanTestProvider wires a Provider directly to a miniredis instance in
+// storage_mode=generic, bypassing SessionInit's real sentinel discovery
+// (which miniredis doesn't speak).
+func newScanTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	return &Provider{
+		maxlifetime: 60,
+		storageMode: storageModeGeneric,
+		serializer:  serializers[defaultSerializerName],
+		keyPrefix:   DefaultKeyPrefix,
+		poollist:    redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+	}
+}
+
+// TestSessionAllCountsNamespacedKeysOnly checks that SessionAll's SCAN only
+// counts this provider's own namespaced keys, not unrelated keys sharing the
+// same Redis instance.
+func TestSessionAllCountsNamespacedKeysOnly(t *testing.T) {
+	rp := newScanTestProvider(t)
+	ctx := context.Background()
+
+	for _, sid := range []string{"sid-1", "sid-2", "sid-3"} {
+		store, err := rp.SessionRead(ctx, sid)
+		if err != nil {
+			t.Fatalf("SessionRead(%s): %v", sid, err)
+		}
+		if err := store.Set(ctx, "k", "v"); err != nil {
+			t.Fatalf("Set(%s): %v", sid, err)
+		}
+		store.SessionRelease(ctx, nil)
+	}
+	if err := rp.poollist.Set("unrelated-key", "v", 0).Err(); err != nil {
+		t.Fatalf("seed unrelated key: %v", err)
+	}
+
+	if got := rp.SessionAll(ctx); got != 3 {
+		t.Fatalf("SessionAll() = %d, want 3", got)
+	}
+}
+
+// TestSessionGCIsANoOp checks that SessionGC doesn't mutate any key: expiry
+// is handled entirely by the TTL Redis already carries on every key this
+// provider writes.
+func TestSessionGCIsANoOp(t *testing.T) {
+	rp := newScanTestProvider(t)
+	key := rp.key("sid-legacy-no-ttl")
+	if err := rp.poollist.Set(key, "v", 0).Err(); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	rp.SessionGC(context.Background())
+
+	ttl, err := rp.poollist.TTL(key).Result()
+	if err != nil {
+		t.Fatalf("TTL: %v", err)
+	}
+	if ttl != -1 {
+		t.Fatalf("SessionGC must not mutate TTLs, got %v want -1 (no expiry)", ttl)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis_sentinel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/flycash/beego-session/pkg/infrastructure/session"
+	"github.com/go-redis/redis/v7"
+)
+
+// TestSerializerRoundTrip exercises every built-in Serializer's
+// Marshal/Unmarshal pair directly, independent of Redis.
+func TestSerializerRoundTrip(t *testing.T) {
+	for name, s := range serializers {
+		t.Run(name, func(t *testing.T) {
+			values := map[interface{}]interface{}{
+				"username": "astaxie",
+				"count":    7,
+			}
+			b, err := s.Marshal(values)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			got, err := s.Unmarshal(b)
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if got["username"] != "astaxie" {
+				t.Errorf("username = %v, want astaxie", got["username"])
+			}
+			// json/msgpack don't preserve the exact numeric type (e.g. json
+			// decodes to float64), only gob does; compare the formatted
+			// value instead of the raw type.
+			if fmt.Sprintf("%v", got["count"]) != "7" {
+				t.Errorf("count = %v, want 7", got["count"])
+			}
+		})
+	}
+}
+
+// TestJSONSerializerProducesPlainJSON checks that the interop promise of
+// "serializer":"json" actually holds: a session released through the
+// provider lands in Redis as a plain JSON object a non-Go service can read
+// directly, not something only this package's gob/json helpers understand.
+func TestJSONSerializerProducesPlainJSON(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rp := &Provider{
+		maxlifetime: 60,
+		storageMode: storageModeGeneric,
+		serializer:  serializers["json"],
+		keyPrefix:   DefaultKeyPrefix,
+		poollist:    redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+	}
+	ctx := context.Background()
+
+	store, err := rp.SessionRead(ctx, "sid-json")
+	if err != nil {
+		t.Fatalf("SessionRead: %v", err)
+	}
+	if err := store.Set(ctx, "username", "astaxie"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	store.SessionRelease(ctx, nil)
+
+	raw, err := rp.poollist.Get(rp.key("sid-json")).Result()
+	if err != nil {
+		t.Fatalf("Get raw: %v", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		t.Fatalf("stored value isn't plain JSON: %v", err)
+	}
+	if m["username"] != "astaxie" {
+		t.Errorf("username = %v, want astaxie", m["username"])
+	}
+}
+
+// TestDecodeValuesFallsBackToGobForLegacyBlobs checks the backward
+// compatibility path the request explicitly calls out: a session written
+// before the serializer was configurable (or before this provider switched
+// to a non-gob serializer) must still be readable.
+func TestDecodeValuesFallsBackToGobForLegacyBlobs(t *testing.T) {
+	rp := &Provider{serializer: serializers["json"]}
+
+	legacy, err := session.EncodeGob(map[interface{}]interface{}{"username": "astaxie"})
+	if err != nil {
+		t.Fatalf("EncodeGob: %v", err)
+	}
+
+	kv, err := rp.decodeValues(legacy)
+	if err != nil {
+		t.Fatalf("decodeValues: %v", err)
+	}
+	if kv["username"] != "astaxie" {
+		t.Errorf("username = %v, want astaxie", kv["username"])
+	}
+}
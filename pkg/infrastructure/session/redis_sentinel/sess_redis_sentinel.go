@@ -20,8 +20,10 @@
 //
 // Usage:
 // import(
-//   _ "github.com/flycash/beego-session/session/redis_sentinel"
-//   "github.com/flycash/beego-session/session"
+//
+//	_ "github.com/flycash/beego-session/pkg/infrastructure/session/redis_sentinel"
+//	"github.com/flycash/beego-session/pkg/infrastructure/session"
+//
 // )
 //
 //	func init() {
@@ -33,7 +35,11 @@
 package redis_sentinel
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -42,6 +48,7 @@ import (
 
 	"github.com/flycash/beego-session/pkg/infrastructure/session"
 	"github.com/go-redis/redis/v7"
+	"github.com/vmihailenco/msgpack/v4"
 )
 
 var redispder = &Provider{}
@@ -49,17 +56,150 @@ var redispder = &Provider{}
 // DefaultPoolSize redis_sentinel default pool size
 var DefaultPoolSize = 100
 
+// DefaultKeyPrefix namespaces every Redis key this provider writes, so
+// operators can SCAN for `<prefix>*` to monitor or garbage collect
+// sessions without touching unrelated keys.
+var DefaultKeyPrefix = "beego_session:"
+
+// Storage modes for SessionStore, selected via the Provider's
+// "storage_mode" config field.
+const (
+	// storageModeGeneric keeps values in memory and writes the whole
+	// session as a single blob, encoded with the Provider's Serializer,
+	// in SessionRelease (the original behaviour).
+	storageModeGeneric = "generic"
+	// storageModeHash maps each session to a Redis HASH and writes
+	// through on every Set/Delete, so concurrent requests for the same
+	// sid don't clobber each other's writes.
+	storageModeHash = "hash"
+)
+
+// defaultSerializerName is used when the "serializer" config field is
+// absent, and is what keeps legacy comma-separated savePath values
+// working unchanged.
+const defaultSerializerName = "gob"
+
+// Serializer marshals and unmarshals the whole session values map for
+// storage as a single Redis value. Built-in implementations are
+// registered under "gob", "json" and "msgpack"; select one via the
+// "serializer" field in a JSON savePath. Unlike gob, the json and
+// msgpack serializers produce blobs other services can read directly
+// from Redis.
+type Serializer interface {
+	Marshal(values map[interface{}]interface{}) ([]byte, error)
+	Unmarshal(b []byte) (map[interface{}]interface{}, error)
+}
+
+var serializers = map[string]Serializer{}
+
+// RegisterSerializer makes a named Serializer available for selection
+// via the "serializer" config field. It is meant to be called from
+// init(), following the same pattern as session.Register.
+func RegisterSerializer(name string, s Serializer) {
+	serializers[name] = s
+}
+
+func init() {
+	RegisterSerializer("gob", gobSerializer{})
+	RegisterSerializer("json", jsonSerializer{})
+	RegisterSerializer("msgpack", msgpackSerializer{})
+}
+
+// gobSerializer is the original encoding used by this provider.
+type gobSerializer struct{}
+
+func (gobSerializer) Marshal(values map[interface{}]interface{}) ([]byte, error) {
+	return session.EncodeGob(values)
+}
+
+func (gobSerializer) Unmarshal(b []byte) (map[interface{}]interface{}, error) {
+	return session.DecodeGob(b)
+}
+
+// jsonSerializer stores values as a JSON object, so a dashboard or other
+// non-Go service can read sessions straight out of Redis. Keys are
+// stringified, matching how beego session keys are used in practice.
+type jsonSerializer struct{}
+
+func (jsonSerializer) Marshal(values map[interface{}]interface{}) ([]byte, error) {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		m[fieldName(k)] = v
+	}
+	return json.Marshal(m)
+}
+
+func (jsonSerializer) Unmarshal(b []byte) (map[interface{}]interface{}, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	values := make(map[interface{}]interface{}, len(m))
+	for k, v := range m {
+		values[k] = v
+	}
+	return values, nil
+}
+
+// msgpackSerializer stores values as msgpack, which unlike JSON can
+// round-trip non-string keys.
+type msgpackSerializer struct{}
+
+func (msgpackSerializer) Marshal(values map[interface{}]interface{}) ([]byte, error) {
+	return msgpack.Marshal(values)
+}
+
+func (msgpackSerializer) Unmarshal(b []byte) (map[interface{}]interface{}, error) {
+	var values map[interface{}]interface{}
+	if err := msgpack.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// decodeValues unmarshals a whole-session blob with the provider's
+// configured serializer, falling back to gob so that values written
+// before the serializer was configurable (or before this field existed)
+// keep working.
+func (rp *Provider) decodeValues(b []byte) (map[interface{}]interface{}, error) {
+	if len(b) > 0 && b[0] == '{' {
+		if kv, err := serializers["json"].Unmarshal(b); err == nil {
+			return kv, nil
+		}
+	}
+	if kv, err := rp.serializer.Unmarshal(b); err == nil {
+		return kv, nil
+	}
+	return serializers[defaultSerializerName].Unmarshal(b)
+}
+
 // SessionStore redis_sentinel session store
 type SessionStore struct {
 	p           *redis.Client
 	sid         string
+	key         string // namespaced Redis key, i.e. Provider.keyPrefix+sid
 	lock        sync.RWMutex
 	values      map[interface{}]interface{}
 	maxlifetime int64
+	storageMode string
+	serializer  Serializer
+	lastErr     error
 }
 
 // Set value in redis_sentinel session
 func (rs *SessionStore) Set(ctx context.Context, key, value interface{}) error {
+	if rs.storageMode == storageModeHash {
+		b, err := encodeValue(value)
+		if err != nil {
+			return err
+		}
+		pipe := rs.p.TxPipeline()
+		pipe.HSet(rs.key, fieldName(key), b)
+		pipe.Expire(rs.key, time.Duration(rs.maxlifetime)*time.Second)
+		_, err = pipe.Exec()
+		return err
+	}
+
 	rs.lock.Lock()
 	defer rs.lock.Unlock()
 	rs.values[key] = value
@@ -68,6 +208,18 @@ func (rs *SessionStore) Set(ctx context.Context, key, value interface{}) error {
 
 // Get value in redis_sentinel session
 func (rs *SessionStore) Get(ctx context.Context, key interface{}) interface{} {
+	if rs.storageMode == storageModeHash {
+		b, err := rs.p.HGet(rs.key, fieldName(key)).Bytes()
+		if err != nil {
+			return nil
+		}
+		v, err := decodeValue(b)
+		if err != nil {
+			return nil
+		}
+		return v
+	}
+
 	rs.lock.RLock()
 	defer rs.lock.RUnlock()
 	if v, ok := rs.values[key]; ok {
@@ -78,6 +230,14 @@ func (rs *SessionStore) Get(ctx context.Context, key interface{}) interface{} {
 
 // Delete value in redis_sentinel session
 func (rs *SessionStore) Delete(ctx context.Context, key interface{}) error {
+	if rs.storageMode == storageModeHash {
+		pipe := rs.p.TxPipeline()
+		pipe.HDel(rs.key, fieldName(key))
+		pipe.Expire(rs.key, time.Duration(rs.maxlifetime)*time.Second)
+		_, err := pipe.Exec()
+		return err
+	}
+
 	rs.lock.Lock()
 	defer rs.lock.Unlock()
 	delete(rs.values, key)
@@ -85,7 +245,11 @@ func (rs *SessionStore) Delete(ctx context.Context, key interface{}) error {
 }
 
 // Flush clear all values in redis_sentinel session
-func (rs *SessionStore) Flush(context.Context) error {
+func (rs *SessionStore) Flush(ctx context.Context) error {
+	if rs.storageMode == storageModeHash {
+		return rs.p.Del(rs.key).Err()
+	}
+
 	rs.lock.Lock()
 	defer rs.lock.Unlock()
 	rs.values = make(map[interface{}]interface{})
@@ -99,12 +263,66 @@ func (rs *SessionStore) SessionID(context.Context) string {
 
 // SessionRelease save session values to redis_sentinel
 func (rs *SessionStore) SessionRelease(ctx context.Context, w http.ResponseWriter) {
-	b, err := session.EncodeGob(rs.values)
+	if rs.storageMode == storageModeHash {
+		// values are already written through on every Set/Delete, so
+		// just slide the TTL.
+		rs.setLastErr(rs.p.Expire(rs.key, time.Duration(rs.maxlifetime)*time.Second).Err())
+		return
+	}
+
+	if len(rs.values) == 0 {
+		// don't bother writing an empty gob/json/msgpack blob back.
+		rs.setLastErr(rs.p.Del(rs.key).Err())
+		return
+	}
+
+	b, err := rs.serializer.Marshal(rs.values)
 	if err != nil {
+		rs.setLastErr(err)
 		return
 	}
-	c := rs.p
-	c.Set(rs.sid, string(b), time.Duration(rs.maxlifetime)*time.Second)
+	rs.setLastErr(rs.p.Set(rs.key, string(b), time.Duration(rs.maxlifetime)*time.Second).Err())
+}
+
+// setLastErr records the outcome of the last SessionRelease call. Err
+// returns it, since SessionRelease itself can't return an error without
+// breaking the session.Store interface.
+func (rs *SessionStore) setLastErr(err error) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	rs.lastErr = err
+}
+
+// Err returns the error, if any, from the most recent SessionRelease call.
+func (rs *SessionStore) Err() error {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	return rs.lastErr
+}
+
+// fieldName turns a session value key into a Redis hash field name.
+func fieldName(key interface{}) string {
+	return fmt.Sprintf("%v", key)
+}
+
+// encodeValue gob-encodes a single session value for storage in a hash
+// field.
+func encodeValue(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeValue decodes a single session value previously written by
+// encodeValue.
+func decodeValue(b []byte) (interface{}, error) {
+	var value interface{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
 }
 
 // Provider redis_sentinel session provider
@@ -119,13 +337,67 @@ type Provider struct {
 	maxRetries         int
 	poollist           *redis.Client
 	masterName         string
+	storageMode        string
+	serializer         Serializer
+	keyPrefix          string
+}
+
+// key namespaces a sid into the Redis key this provider actually reads
+// and writes.
+func (rp *Provider) key(sid string) string {
+	return rp.keyPrefix + sid
+}
+
+// sentinelConfig is the JSON shape accepted by SessionInit, as an
+// alternative to the legacy comma-separated savePath.
+type sentinelConfig struct {
+	SavePath           string `json:"SavePath"`
+	Poolsize           int    `json:"Poolsize"`
+	Password           string `json:"Password"`
+	DbNum              int    `json:"DbNum"`
+	MasterName         string `json:"MasterName"`
+	IdleTimeout        int    `json:"IdleTimeout"`
+	IdleCheckFrequency int    `json:"IdleCheckFrequency"`
+	MaxRetries         int    `json:"MaxRetries"`
+	// StorageMode selects how session values are persisted: "generic"
+	// (default) encodes the whole values map with Serializer on
+	// SessionRelease, "hash"
+	// stores each key as its own Redis hash field via HSET/HGET/HDEL.
+	//
+	// These last three fields keep their snake_case tags (unlike the
+	// PascalCase ones above) because that's the wire format their
+	// originating requests specify (e.g. "storage_mode":"hash"); Go's
+	// case-insensitive tag matching only folds case, not underscores, so
+	// renaming these to match the other fields would silently break
+	// existing JSON savePath configs.
+	StorageMode string `json:"storage_mode"`
+	// Serializer selects the whole-session codec used in "generic"
+	// storage mode: "gob" (default), "json" or "msgpack".
+	Serializer string `json:"serializer"`
+	// KeyPrefix namespaces every Redis key this provider writes.
+	// Defaults to DefaultKeyPrefix.
+	KeyPrefix string `json:"key_prefix"`
 }
 
 // SessionInit init redis_sentinel session
 // savepath like redis sentinel addr,pool size,password,dbnum,masterName
 // e.g. 127.0.0.1:26379;127.0.0.2:26379,100,1qaz2wsx,0,mymaster
+//
+// savePath may also be a JSON object, matching the pattern used by the
+// couchbase, redis and redis_cluster providers, e.g.
+//
+//	{"SavePath":"127.0.0.1:26379;127.0.0.2:26379","Poolsize":100,"Password":"1qaz2wsx","DbNum":0,"MasterName":"mymaster"}
 func (rp *Provider) SessionInit(ctx context.Context, maxlifetime int64, savePath string) error {
 	rp.maxlifetime = maxlifetime
+	rp.storageMode = storageModeGeneric
+	rp.serializer = serializers[defaultSerializerName]
+	rp.keyPrefix = DefaultKeyPrefix
+
+	savePath = strings.TrimSpace(savePath)
+	if strings.HasPrefix(savePath, "{") {
+		return rp.sessionInitJSON(maxlifetime, savePath)
+	}
+
 	configs := strings.Split(savePath, ",")
 	if len(configs) > 0 {
 		rp.savePath = configs[0]
@@ -163,24 +435,81 @@ func (rp *Provider) SessionInit(ctx context.Context, maxlifetime int64, savePath
 		rp.masterName = "mymaster"
 	}
 	if len(configs) > 5 {
-		timeout, err := strconv.Atoi(configs[4])
+		timeout, err := strconv.Atoi(configs[5])
 		if err == nil && timeout > 0 {
 			rp.idleTimeout = time.Duration(timeout) * time.Second
 		}
 	}
 	if len(configs) > 6 {
-		checkFrequency, err := strconv.Atoi(configs[5])
+		checkFrequency, err := strconv.Atoi(configs[6])
 		if err == nil && checkFrequency > 0 {
 			rp.idleCheckFrequency = time.Duration(checkFrequency) * time.Second
 		}
 	}
 	if len(configs) > 7 {
-		retries, err := strconv.Atoi(configs[6])
+		retries, err := strconv.Atoi(configs[7])
 		if err == nil && retries > 0 {
 			rp.maxRetries = retries
 		}
 	}
 
+	return rp.connect()
+}
+
+// sessionInitJSON initializes the provider from a JSON-encoded savePath,
+// as an alternative to the legacy comma-separated format.
+func (rp *Provider) sessionInitJSON(maxlifetime int64, savePath string) error {
+	cf := &sentinelConfig{}
+	if err := json.Unmarshal([]byte(savePath), cf); err != nil {
+		return err
+	}
+
+	rp.maxlifetime = maxlifetime
+	rp.savePath = cf.SavePath
+	rp.password = cf.Password
+	rp.dbNum = cf.DbNum
+	rp.masterName = cf.MasterName
+	rp.storageMode = cf.StorageMode
+	if rp.storageMode == "" {
+		rp.storageMode = storageModeGeneric
+	}
+	rp.serializer = serializers[cf.Serializer]
+	if rp.serializer == nil {
+		rp.serializer = serializers[defaultSerializerName]
+	}
+	rp.keyPrefix = cf.KeyPrefix
+	if rp.keyPrefix == "" {
+		rp.keyPrefix = DefaultKeyPrefix
+	}
+	if rp.masterName == "" {
+		rp.masterName = "mymaster"
+	}
+	if cf.Poolsize > 0 {
+		rp.poolsize = cf.Poolsize
+	} else {
+		// Unlike the comma-separated path, an explicit 0 here is
+		// indistinguishable from an absent field (JSON unmarshals a
+		// missing "Poolsize" to the int zero value too), so it's always
+		// promoted to DefaultPoolSize. Not worth a *int just to preserve
+		// a pool size of 0, which isn't a meaningful config in practice.
+		rp.poolsize = DefaultPoolSize
+	}
+	if cf.IdleTimeout > 0 {
+		rp.idleTimeout = time.Duration(cf.IdleTimeout) * time.Second
+	}
+	if cf.IdleCheckFrequency > 0 {
+		rp.idleCheckFrequency = time.Duration(cf.IdleCheckFrequency) * time.Second
+	}
+	if cf.MaxRetries > 0 {
+		rp.maxRetries = cf.MaxRetries
+	}
+
+	return rp.connect()
+}
+
+// connect builds the sentinel-backed redis client from the fields
+// already populated on rp, and pings it to surface connection errors early.
+func (rp *Provider) connect() error {
 	rp.poollist = redis.NewFailoverClient(&redis.FailoverOptions{
 		SentinelAddrs:      strings.Split(rp.savePath, ";"),
 		Password:           rp.password,
@@ -197,27 +526,48 @@ func (rp *Provider) SessionInit(ctx context.Context, maxlifetime int64, savePath
 
 // SessionRead read redis_sentinel session by sid
 func (rp *Provider) SessionRead(ctx context.Context, sid string) (session.Store, error) {
+	key := rp.key(sid)
+	ttl := time.Duration(rp.maxlifetime) * time.Second
+
+	if rp.storageMode == storageModeHash {
+		// Values live entirely in the Redis hash; Set/Get/Delete talk
+		// to it directly, so there's nothing to preload here. Slide the
+		// TTL so an active session doesn't expire mid-use; EXPIRE on a
+		// key that doesn't exist yet is a harmless no-op.
+		rp.poollist.Expire(key, ttl)
+		rs := &SessionStore{
+			p:           rp.poollist,
+			sid:         sid,
+			key:         key,
+			values:      make(map[interface{}]interface{}),
+			maxlifetime: rp.maxlifetime,
+			storageMode: rp.storageMode,
+		}
+		return rs, nil
+	}
+
 	var kv map[interface{}]interface{}
-	kvs, err := rp.poollist.Get(sid).Result()
+	kvs, err := rp.poollist.Get(key).Result()
 	if err != nil && err != redis.Nil {
 		return nil, err
 	}
 	if len(kvs) == 0 {
 		kv = make(map[interface{}]interface{})
 	} else {
-		if kv, err = session.DecodeGob([]byte(kvs)); err != nil {
+		if kv, err = rp.decodeValues([]byte(kvs)); err != nil {
 			return nil, err
 		}
+		rp.poollist.Expire(key, ttl)
 	}
 
-	rs := &SessionStore{p: rp.poollist, sid: sid, values: kv, maxlifetime: rp.maxlifetime}
+	rs := &SessionStore{p: rp.poollist, sid: sid, key: key, values: kv, maxlifetime: rp.maxlifetime, storageMode: rp.storageMode, serializer: rp.serializer}
 	return rs, nil
 }
 
 // SessionExist check redis_sentinel session exist by sid
 func (rp *Provider) SessionExist(ctx context.Context, sid string) (bool, error) {
 	c := rp.poollist
-	if existed, err := c.Exists(sid).Result(); err != nil || existed == 0 {
+	if existed, err := c.Exists(rp.key(sid)).Result(); err != nil || existed == 0 {
 		return false, err
 	}
 	return true, nil
@@ -226,15 +576,22 @@ func (rp *Provider) SessionExist(ctx context.Context, sid string) (bool, error)
 // SessionRegenerate generate new sid for redis_sentinel session
 func (rp *Provider) SessionRegenerate(ctx context.Context, oldsid, sid string) (session.Store, error) {
 	c := rp.poollist
+	oldKey, key := rp.key(oldsid), rp.key(sid)
 
-	if existed, err := c.Exists(oldsid).Result(); err != nil || existed == 0 {
-		// oldsid doesn't exists, set the new sid directly
+	if existed, err := c.Exists(oldKey).Result(); err != nil || existed == 0 {
+		// oldsid doesn't exist, set the new sid directly.
 		// ignore error here, since if it return error
 		// the existed value will be 0
-		c.Set(sid, "", time.Duration(rp.maxlifetime)*time.Second)
+		if rp.storageMode != storageModeHash {
+			// In hash mode there's nothing to seed: the key must stay a
+			// HASH, and SessionStore.Set will create it with HSET on the
+			// first write. Writing a placeholder STRING here would make
+			// that first HSET fail with WRONGTYPE.
+			c.Set(key, "", time.Duration(rp.maxlifetime)*time.Second)
+		}
 	} else {
-		c.Rename(oldsid, sid)
-		c.Expire(sid, time.Duration(rp.maxlifetime)*time.Second)
+		c.Rename(oldKey, key)
+		c.Expire(key, time.Duration(rp.maxlifetime)*time.Second)
 	}
 	return rp.SessionRead(context.Background(), sid)
 }
@@ -242,17 +599,43 @@ func (rp *Provider) SessionRegenerate(ctx context.Context, oldsid, sid string) (
 // SessionDestroy delete redis session by id
 func (rp *Provider) SessionDestroy(ctx context.Context, sid string) error {
 	c := rp.poollist
-	c.Del(sid)
+	c.Del(rp.key(sid))
 	return nil
 }
 
-// SessionGC Impelment method, no used.
+// scanKeys walks every Redis key in this provider's namespace using a
+// non-blocking SCAN cursor, invoking fn for each one found.
+func (rp *Provider) scanKeys(fn func(key string)) error {
+	match := rp.keyPrefix + "*"
+	var cursor uint64
+	for {
+		keys, next, err := rp.poollist.Scan(cursor, match, 100).Result()
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			fn(k)
+		}
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// SessionGC is a no-op: every key this provider writes already carries a
+// Redis TTL, set in SessionRelease and slid forward on every SessionRead,
+// so expiry is handled entirely by Redis itself.
 func (rp *Provider) SessionGC(context.Context) {
 }
 
 // SessionAll return all activeSession
 func (rp *Provider) SessionAll(context.Context) int {
-	return 0
+	count := 0
+	_ = rp.scanKeys(func(string) {
+		count++
+	})
+	return count
 }
 
 func init() {
@@ -0,0 +1,148 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis_sentinel
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestSessionInitLegacyPath exercises the comma-separated savePath format,
+// checking every field SessionInit parses out of it before it dials the
+// sentinel (which isn't reachable in this test, so SessionInit is expected
+// to return a non-nil error from the Ping).
+func TestSessionInitLegacyPath(t *testing.T) {
+	rp := &Provider{}
+	_ = rp.SessionInit(context.Background(), 3600, "127.0.0.1:26379;127.0.0.2:26379,50,1qaz2wsx,2,mymaster,30,15,4")
+
+	if rp.savePath != "127.0.0.1:26379;127.0.0.2:26379" {
+		t.Errorf("savePath = %q", rp.savePath)
+	}
+	if rp.poolsize != 50 {
+		t.Errorf("poolsize = %d, want 50", rp.poolsize)
+	}
+	if rp.password != "1qaz2wsx" {
+		t.Errorf("password = %q, want 1qaz2wsx", rp.password)
+	}
+	if rp.dbNum != 2 {
+		t.Errorf("dbNum = %d, want 2", rp.dbNum)
+	}
+	if rp.masterName != "mymaster" {
+		t.Errorf("masterName = %q, want mymaster", rp.masterName)
+	}
+	if rp.idleTimeout != 30*time.Second {
+		t.Errorf("idleTimeout = %v, want 30s", rp.idleTimeout)
+	}
+	if rp.idleCheckFrequency != 15*time.Second {
+		t.Errorf("idleCheckFrequency = %v, want 15s", rp.idleCheckFrequency)
+	}
+	if rp.maxRetries != 4 {
+		t.Errorf("maxRetries = %d, want 4", rp.maxRetries)
+	}
+}
+
+// TestSessionInitLegacyPathDefaults checks the defaults applied when the
+// comma-separated savePath only carries the sentinel address.
+func TestSessionInitLegacyPathDefaults(t *testing.T) {
+	rp := &Provider{}
+	_ = rp.SessionInit(context.Background(), 3600, "127.0.0.1:26379")
+
+	if rp.poolsize != DefaultPoolSize {
+		t.Errorf("poolsize = %d, want default %d", rp.poolsize, DefaultPoolSize)
+	}
+	if rp.dbNum != 0 {
+		t.Errorf("dbNum = %d, want 0", rp.dbNum)
+	}
+	if rp.masterName != "mymaster" {
+		t.Errorf("masterName = %q, want default mymaster", rp.masterName)
+	}
+}
+
+// TestSessionInitJSONPath exercises the JSON savePath format, the
+// alternative this provider offers alongside the legacy comma-separated one.
+func TestSessionInitJSONPath(t *testing.T) {
+	rp := &Provider{}
+	cfg := `{"SavePath":"127.0.0.1:26379;127.0.0.2:26379","Poolsize":80,"Password":"pw","DbNum":3,"MasterName":"leader","IdleTimeout":20,"IdleCheckFrequency":10,"MaxRetries":6}`
+	_ = rp.SessionInit(context.Background(), 1800, cfg)
+
+	if rp.savePath != "127.0.0.1:26379;127.0.0.2:26379" {
+		t.Errorf("savePath = %q", rp.savePath)
+	}
+	if rp.poolsize != 80 {
+		t.Errorf("poolsize = %d, want 80", rp.poolsize)
+	}
+	if rp.password != "pw" {
+		t.Errorf("password = %q, want pw", rp.password)
+	}
+	if rp.dbNum != 3 {
+		t.Errorf("dbNum = %d, want 3", rp.dbNum)
+	}
+	if rp.masterName != "leader" {
+		t.Errorf("masterName = %q, want leader", rp.masterName)
+	}
+	if rp.idleTimeout != 20*time.Second {
+		t.Errorf("idleTimeout = %v, want 20s", rp.idleTimeout)
+	}
+	if rp.idleCheckFrequency != 10*time.Second {
+		t.Errorf("idleCheckFrequency = %v, want 10s", rp.idleCheckFrequency)
+	}
+	if rp.maxRetries != 6 {
+		t.Errorf("maxRetries = %d, want 6", rp.maxRetries)
+	}
+}
+
+// TestSessionInitJSONPathDefaults checks the defaults applied when the JSON
+// savePath only carries the sentinel address.
+func TestSessionInitJSONPathDefaults(t *testing.T) {
+	rp := &Provider{}
+	_ = rp.SessionInit(context.Background(), 1800, `{"SavePath":"127.0.0.1:26379"}`)
+
+	if rp.poolsize != DefaultPoolSize {
+		t.Errorf("poolsize = %d, want default %d", rp.poolsize, DefaultPoolSize)
+	}
+	if rp.masterName != "mymaster" {
+		t.Errorf("masterName = %q, want default mymaster", rp.masterName)
+	}
+	if rp.storageMode != storageModeGeneric {
+		t.Errorf("storageMode = %q, want default generic", rp.storageMode)
+	}
+	if rp.serializer != serializers[defaultSerializerName] {
+		t.Errorf("serializer not defaulted to %q", defaultSerializerName)
+	}
+	if rp.keyPrefix != DefaultKeyPrefix {
+		t.Errorf("keyPrefix = %q, want default %q", rp.keyPrefix, DefaultKeyPrefix)
+	}
+}
+
+// TestSessionInitJSONPathStorageOptions checks that storage_mode,
+// serializer and key_prefix all wire up correctly through SessionInit's
+// JSON path, using the exact snake_case keys their originating requests
+// specify (e.g. "storage_mode":"hash").
+func TestSessionInitJSONPathStorageOptions(t *testing.T) {
+	rp := &Provider{}
+	cfg := `{"SavePath":"127.0.0.1:26379","storage_mode":"hash","serializer":"json","key_prefix":"custom:"}`
+	_ = rp.SessionInit(context.Background(), 1800, cfg)
+
+	if rp.storageMode != storageModeHash {
+		t.Errorf("storageMode = %q, want %q", rp.storageMode, storageModeHash)
+	}
+	if rp.serializer != serializers["json"] {
+		t.Errorf("serializer not wired to json")
+	}
+	if rp.keyPrefix != "custom:" {
+		t.Errorf("keyPrefix = %q, want custom:", rp.keyPrefix)
+	}
+}
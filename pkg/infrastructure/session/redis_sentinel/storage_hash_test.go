@@ -0,0 +1,93 @@
+// Copyright 2014 beego Author. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis_sentinel
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v7"
+)
+
+// newHashTestProvider wires a Provider directly to a miniredis instance in
+// storage_mode=hash, bypassing SessionInit's real sentinel discovery (which
+// miniredis doesn't speak).
+func newHashTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	return &Provider{
+		maxlifetime: 60,
+		storageMode: storageModeHash,
+		keyPrefix:   DefaultKeyPrefix,
+		poollist:    redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+	}
+}
+
+// TestSessionRegenerateHashMode reproduces the anonymous-page-view-then-login
+// flow: SessionRegenerate is called for an oldsid that was never written, so
+// it must leave the new key in a state SessionStore.Set can still HSET into,
+// rather than seeding it as a STRING.
+func TestSessionRegenerateHashMode(t *testing.T) {
+	rp := newHashTestProvider(t)
+	ctx := context.Background()
+
+	store, err := rp.SessionRegenerate(ctx, "anon-sid-never-written", "logged-in-sid")
+	if err != nil {
+		t.Fatalf("SessionRegenerate: %v", err)
+	}
+	if err := store.Set(ctx, "user_id", 42); err != nil {
+		t.Fatalf("Set after regenerate: %v", err)
+	}
+	if got := store.Get(ctx, "user_id"); got != 42 {
+		t.Fatalf("Get(user_id) = %v, want 42", got)
+	}
+}
+
+// TestHashModeConcurrentWrites demonstrates that two concurrent
+// SessionStores for the same sid don't clobber each other's writes, which is
+// the whole point of storage_mode=hash over the whole-map generic mode.
+func TestHashModeConcurrentWrites(t *testing.T) {
+	rp := newHashTestProvider(t)
+	ctx := context.Background()
+
+	const sid = "shared-sid"
+	storeA, err := rp.SessionRead(ctx, sid)
+	if err != nil {
+		t.Fatalf("SessionRead A: %v", err)
+	}
+	storeB, err := rp.SessionRead(ctx, sid)
+	if err != nil {
+		t.Fatalf("SessionRead B: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); storeA.Set(ctx, "a", "from-a") }()
+	go func() { defer wg.Done(); storeB.Set(ctx, "b", "from-b") }()
+	wg.Wait()
+
+	final, err := rp.SessionRead(ctx, sid)
+	if err != nil {
+		t.Fatalf("SessionRead final: %v", err)
+	}
+	if got := final.Get(ctx, "a"); got != "from-a" {
+		t.Errorf("Get(a) = %v, want from-a", got)
+	}
+	if got := final.Get(ctx, "b"); got != "from-b" {
+		t.Errorf("Get(b) = %v, want from-b", got)
+	}
+}